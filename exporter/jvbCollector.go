@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -57,11 +58,23 @@ func newMetric(name string, metricType prometheus.ValueType, help string,
 //NamePrefix for naming the metrics, see https://godoc.org/github.com/prometheus/client_golang/prometheus#Opts
 //Retention defines how long the jvb collector will consider a set of stats valid, once retention has passed since the last update,
 //	the stats set will not be included in the collect output anymore
+//Permissive, when set, makes Collect auto-register a gauge for any numeric stat name seen on the wire that
+//	isn't already registered via RegisterStat, so new JVB releases surface their stats without a code change
+//statsSets is keyed by jvbIdentifier and guarded by mutex, since Update and Collect run from different
+//	goroutines (the presence/scrape pipeline and the HTTP handler, respectively)
+//metrics is keyed by the un-prefixed stat name so Collect can dispatch in a single pass instead of scanning
+//	every metric for every stat
 type JvbCollector struct {
-	NamePrefix string
-	Retention  time.Duration
-	statsSets  []statsSet
-	metrics    []metric
+	NamePrefix  string
+	Retention   time.Duration
+	Permissive  bool
+	mutex       sync.RWMutex
+	statsSets   map[string]statsSet
+	metrics     map[string]metric
+	constLabels prometheus.Labels
+
+	statsAgeDesc       *prometheus.Desc
+	knownInstancesDesc *prometheus.Desc
 }
 
 //NewJvbCollector initializes a Jvb collector
@@ -69,6 +82,8 @@ type JvbCollector struct {
 func NewJvbCollector(namespace, subsystem string, retention time.Duration) *JvbCollector {
 	var collector = &JvbCollector{
 		Retention: retention,
+		statsSets: make(map[string]statsSet),
+		metrics:   make(map[string]metric),
 	}
 
 	var namePrefix = ""
@@ -87,268 +102,278 @@ func NewJvbCollector(namespace, subsystem string, retention time.Duration) *JvbC
 	var constLabels = prometheus.Labels{
 		"app": "jitsi",
 	}
+	collector.constLabels = constLabels
 
 	//add metrics
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"packet_rate_download", prometheus.GaugeValue,
-		"download packet rate", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("packet_rate_download", prometheus.GaugeValue, "download packet rate")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"conference_sizes", prometheus.UntypedValue,
-		"histogram of conference sizes (ie. how many conferences have 5 participants and so on)", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("conference_sizes", prometheus.UntypedValue, "histogram of conference sizes (ie. how many conferences have 5 participants and so on). Bucket upper bounds are participant counts, and the _sum is the weighted participant mass, so rate(_sum)/rate(_count) gives the average conference size.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_packets_sent_octo", prometheus.CounterValue,
-		"total number of octo packets sent", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_packets_sent_octo", prometheus.CounterValue, "total number of octo packets sent")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_loss_degraded_participant_seconds", prometheus.CounterValue,
-		"The total number of participant-seconds that are loss-degraded.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_loss_degraded_participant_seconds", prometheus.CounterValue, "The total number of participant-seconds that are loss-degraded.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"bit_rate_download", prometheus.GaugeValue,
-		"download rate kbit/s", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("bit_rate_download", prometheus.GaugeValue, "download rate kbit/s")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"jitter_aggregate", prometheus.GaugeValue,
-		"Experimental. An average value (in milliseconds) of the jitter calculated for incoming and outgoing streams. This hasn't been tested and it is currently not known whether the values are correct or not.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("jitter_aggregate", prometheus.GaugeValue, "Experimental. An average value (in milliseconds) of the jitter calculated for incoming and outgoing streams. This hasn't been tested and it is currently not known whether the values are correct or not.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_packets_received", prometheus.CounterValue,
-		"Total number of packets received", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_packets_received", prometheus.CounterValue, "Total number of packets received")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"rtt_aggregate", prometheus.GaugeValue,
-		"An average value (in milliseconds) of the RTT across all streams.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("rtt_aggregate", prometheus.GaugeValue, "An average value (in milliseconds) of the RTT across all streams.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"packet_rate_upload", prometheus.GaugeValue,
-		"Upload packets/s", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("packet_rate_upload", prometheus.GaugeValue, "Upload packets/s")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"conferences", prometheus.GaugeValue,
-		"The current number of conferences hosted by the bridge", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("conferences", prometheus.GaugeValue, "The current number of conferences hosted by the bridge")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"participants", prometheus.GaugeValue,
-		"The current number of participants.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("participants", prometheus.GaugeValue, "The current number of participants.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_loss_limited_participant_seconds", prometheus.CounterValue,
-		"The total number of participant-seconds that are loss-limited.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_loss_limited_participant_seconds", prometheus.CounterValue, "The total number of participant-seconds that are loss-limited.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"largest_conference", prometheus.GaugeValue,
-		"The current number of participants in the largest conference", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("largest_conference", prometheus.GaugeValue, "The current number of participants in the largest conference")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_packets_sent", prometheus.CounterValue,
-		"The total number of packets sent.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_packets_sent", prometheus.CounterValue, "The total number of packets sent.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_data_channel_messages_sent", prometheus.CounterValue,
-		"The total number of data channel messages sent.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_data_channel_messages_sent", prometheus.CounterValue, "The total number of data channel messages sent.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_bytes_received_octo", prometheus.CounterValue,
-		"The total number octo bytes sent.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_bytes_received_octo", prometheus.CounterValue, "The total number octo bytes received.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"threads", prometheus.GaugeValue,
-		"The current number of threads.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("threads", prometheus.GaugeValue, "The current number of threads.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_colibri_web_socket_messages_received", prometheus.CounterValue,
-		"The total number messages received through COLIBRI web sockets.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_colibri_web_socket_messages_received", prometheus.CounterValue, "The total number messages received through COLIBRI web sockets.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"videochannels", prometheus.GaugeValue,
-		"The current number of videochannels.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("videochannels", prometheus.GaugeValue, "The current number of videochannels.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_packets_received_octo", prometheus.CounterValue,
-		"Total octo packets received.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_packets_received_octo", prometheus.CounterValue, "Total octo packets received.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_colibri_web_socket_messages_sent", prometheus.CounterValue,
-		"The total number messages sent through COLIBRI web sockets.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_colibri_web_socket_messages_sent", prometheus.CounterValue, "The total number messages sent through COLIBRI web sockets.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_bytes_sent_octo", prometheus.CounterValue,
-		"Total octo bytes sent.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_bytes_sent_octo", prometheus.CounterValue, "Total octo bytes sent.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_data_channel_messages_received", prometheus.CounterValue,
-		"Total data channel messages received.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_data_channel_messages_received", prometheus.CounterValue, "Total data channel messages received.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_conference_seconds", prometheus.CounterValue,
-		"The sum of the lengths of all completed conferences, in seconds.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_conference_seconds", prometheus.CounterValue, "The sum of the lengths of all completed conferences, in seconds.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_bytes_received", prometheus.CounterValue,
-		"Total bytes received.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_bytes_received", prometheus.CounterValue, "Total bytes received.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_loss_controlled_participant_seconds", prometheus.CounterValue,
-		"The total number of participant-seconds that are loss-controlled.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_loss_controlled_participant_seconds", prometheus.CounterValue, "The total number of participant-seconds that are loss-controlled.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_partially_failed_conferences", prometheus.CounterValue,
-		"The total number of partially failed conferences on the bridge. A conference is marked as partially failed when some of its channels has failed. A channel is marked as failed if it had no payload activity.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_partially_failed_conferences", prometheus.CounterValue, "The total number of partially failed conferences on the bridge. A conference is marked as partially failed when some of its channels has failed. A channel is marked as failed if it had no payload activity.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"bit_rate_upload", prometheus.GaugeValue,
-		"Current upload rate in kbit/s.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("bit_rate_upload", prometheus.GaugeValue, "Current upload rate in kbit/s.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_conferences_completed", prometheus.CounterValue,
-		"Total conferences completed.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_conferences_completed", prometheus.CounterValue, "Total conferences completed.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_bytes_sent", prometheus.CounterValue,
-		"The number of total bytes sent.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_bytes_sent", prometheus.CounterValue, "The number of total bytes sent.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_failed_conferences", prometheus.CounterValue,
-		"The total number of failed conferences on the bridge. A conference is marked as failed when all of its channels have failed. A channel is marked as failed if it had no payload activity.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_failed_conferences", prometheus.CounterValue, "The total number of failed conferences on the bridge. A conference is marked as failed when all of its channels have failed. A channel is marked as failed if it had no payload activity.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"conferences_by_audio_senders", prometheus.UntypedValue,
-		"Histogram of conferences by number of audio senders (ie. how many conferences have 5 audio senders and so on)", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("conferences_by_audio_senders", prometheus.UntypedValue, "Histogram of conferences by number of audio senders (ie. how many conferences have 5 audio senders and so on). Bucket upper bounds are audio sender counts, and the _sum is the weighted sender mass, so rate(_sum)/rate(_count) gives the average number of audio senders per conference.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"conferences_by_video_senders", prometheus.UntypedValue,
-		"Histogram of conferences by number of video senders (ie. how many conferences have 5 video senders and so on)", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("conferences_by_video_senders", prometheus.UntypedValue, "Histogram of conferences by number of video senders (ie. how many conferences have 5 video senders and so on). Bucket upper bounds are video sender counts, and the _sum is the weighted sender mass, so rate(_sum)/rate(_count) gives the average number of video senders per conference.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"dtls_failed_endpoints", prometheus.GaugeValue,
-		"The number of failed dtls endpoints on the bridge. An endpoint has failed DTLS if it has completed ICE but not.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("dtls_failed_endpoints", prometheus.GaugeValue, "The number of failed dtls endpoints on the bridge. An endpoint has failed DTLS if it has completed ICE but not.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"endpoints_sending_audio", prometheus.GaugeValue,
-		"The number of endpoints which are sending audio.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("endpoints_sending_audio", prometheus.GaugeValue, "The number of endpoints which are sending audio.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"endpoints_sending_video", prometheus.GaugeValue,
-		"The number of endpoints which are sending video.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("endpoints_sending_video", prometheus.GaugeValue, "The number of endpoints which are sending video.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"inactive_conferences", prometheus.GaugeValue,
-		"The number of inactive conferences.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("inactive_conferences", prometheus.GaugeValue, "The number of inactive conferences.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"inactive_endpoints", prometheus.GaugeValue,
-		"The number of inactive endpoints.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("inactive_endpoints", prometheus.GaugeValue, "The number of inactive endpoints.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"incoming_loss", prometheus.GaugeValue,
-		"The percentage of incoming packets which are lost.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("incoming_loss", prometheus.GaugeValue, "The percentage of incoming packets which are lost.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"muc_clients_configured", prometheus.GaugeValue,
-		"The number of configured muc clients.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("muc_clients_configured", prometheus.GaugeValue, "The number of configured muc clients.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"muc_clients_connected", prometheus.GaugeValue,
-		"The number of connected muc clients.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("muc_clients_connected", prometheus.GaugeValue, "The number of connected muc clients.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"mucs_configured", prometheus.GaugeValue,
-		"The number of configured mucs.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("mucs_configured", prometheus.GaugeValue, "The number of configured mucs.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"mucs_joined", prometheus.GaugeValue,
-		"The number of joined mucs.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("mucs_joined", prometheus.GaugeValue, "The number of joined mucs.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"num_eps_no_msg_transport_after_delay", prometheus.GaugeValue,
-		"The number of endpoints with no message transport after delay.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("num_eps_no_msg_transport_after_delay", prometheus.GaugeValue, "The number of endpoints with no message transport after delay.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"octo_conferences", prometheus.GaugeValue,
-		"The number of conferences using Octo", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("octo_conferences", prometheus.GaugeValue, "The number of conferences using Octo")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"octo_endpoints", prometheus.GaugeValue,
-		"The number of endpoints using Octo", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("octo_endpoints", prometheus.GaugeValue, "The number of endpoints using Octo")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"octo_receive_bitrate", prometheus.GaugeValue,
-		"The bitrate of data being received from Octo", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("octo_receive_bitrate", prometheus.GaugeValue, "The bitrate of data being received from Octo")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"octo_receive_packet_rate", prometheus.GaugeValue,
-		"The rate of packets being received from Octo", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("octo_receive_packet_rate", prometheus.GaugeValue, "The rate of packets being received from Octo")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"octo_send_bitrate", prometheus.GaugeValue,
-		"The bitrate of data being send to Octo", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("octo_send_bitrate", prometheus.GaugeValue, "The bitrate of data being send to Octo")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"octo_send_packet_rate", prometheus.GaugeValue,
-		"The rate of packets being send to Octo", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("octo_send_packet_rate", prometheus.GaugeValue, "The rate of packets being send to Octo")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"outgoing_loss", prometheus.GaugeValue,
-		"The percentage of outgoing packets which are lost.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("outgoing_loss", prometheus.GaugeValue, "The percentage of outgoing packets which are lost.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"overall_loss", prometheus.GaugeValue,
-		"The overall percentage of packets which are lost.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("overall_loss", prometheus.GaugeValue, "The overall percentage of packets which are lost.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"p2p_conferences", prometheus.GaugeValue,
-		"The number of P2P conferences.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("p2p_conferences", prometheus.GaugeValue, "The number of P2P conferences.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"receive_only_endpoints", prometheus.GaugeValue,
-		"The number of endpoints which are sending neither audio nor video and aren't inactive.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("receive_only_endpoints", prometheus.GaugeValue, "The number of endpoints which are sending neither audio nor video and aren't inactive.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"stress_level", prometheus.GaugeValue,
-		"The stress level.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("stress_level", prometheus.GaugeValue, "The stress level.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_conferences_created", prometheus.CounterValue,
-		"The total number of conferences created.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_conferences_created", prometheus.CounterValue, "The total number of conferences created.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_dominant_speaker_changes", prometheus.CounterValue,
-		"The total number of dominant speaker changes.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_dominant_speaker_changes", prometheus.CounterValue, "The total number of dominant speaker changes.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_ice_failed", prometheus.CounterValue,
-		"The total number of failed ICE connections.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_ice_failed", prometheus.CounterValue, "The total number of failed ICE connections.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_ice_succeeded", prometheus.CounterValue,
-		"The total number of succeeded ICE connections.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_ice_succeeded", prometheus.CounterValue, "The total number of succeeded ICE connections.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_ice_succeeded_relayed", prometheus.CounterValue,
-		"The total number of succeeded ICE connections which are relayed.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_ice_succeeded_relayed", prometheus.CounterValue, "The total number of succeeded ICE connections which are relayed.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_packets_dropped_octo", prometheus.CounterValue,
-		"The total number of packets dropped to or from Octo.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_packets_dropped_octo", prometheus.CounterValue, "The total number of packets dropped to or from Octo.")
 
-	collector.metrics = append(collector.metrics, newMetric(collector.NamePrefix+"total_participants", prometheus.CounterValue,
-		"The total number of participants.", []string{"jvb_instance"}, constLabels))
+	collector.RegisterStat("total_participants", prometheus.CounterValue, "The total number of participants.")
+
+	collector.statsAgeDesc = prometheus.NewDesc(collector.NamePrefix+"jvb_stats_age_seconds",
+		"Time in seconds since the last stats update was received for this jvb_instance.", []string{"jvb_instance"}, constLabels)
+
+	collector.knownInstancesDesc = prometheus.NewDesc(collector.NamePrefix+"jvb_known_instances",
+		"The number of jvb_instances currently tracked by the collector, regardless of retention.", nil, constLabels)
 
 	return collector
 }
 
+//RegisterStat registers a metric for the given un-prefixed stat name (as reported by the JVB, e.g.
+//"total_bytes_sent"), so Collect starts publishing it the next time a JVB reports it. This lets users
+//extend coverage for new JVB stats without recompiling. Re-registering an already known name is a no-op,
+//so permissive mode can call it without clobbering an explicit registration.
+func (c *JvbCollector) RegisterStat(name string, metricType prometheus.ValueType, help string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.metrics[name]; exists {
+		return
+	}
+
+	c.metrics[name] = newMetric(c.NamePrefix+name, metricType, help, []string{"jvb_instance"}, c.constLabels)
+}
+
 //Describe implements prometheus.Collector interface
 func (c *JvbCollector) Describe(desc chan<- *prometheus.Desc) {
 	for _, m := range c.metrics {
 		desc <- m.desc
 	}
+	desc <- c.statsAgeDesc
+	desc <- c.knownInstancesDesc
 }
 
 //Collect implements prometheus.Collector interface
 func (c *JvbCollector) Collect(metrics chan<- prometheus.Metric) {
+	if c.Permissive {
+		c.registerUnknownStats()
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	metrics <- prometheus.MustNewConstMetric(c.knownInstancesDesc, prometheus.GaugeValue, float64(len(c.statsSets)))
+
+	for _, set := range c.statsSets {
+		metrics <- prometheus.MustNewConstMetric(c.statsAgeDesc, prometheus.GaugeValue,
+			time.Since(set.lastUpdated).Seconds(), set.jvbIdentifier)
+
+		if time.Since(set.lastUpdated) > c.Retention {
+			continue
+		}
+
+		//dispatch straight off the name-indexed map instead of scanning every metric for every stat
+		for _, stat := range set.stats.Stats {
+			metric, ok := c.metrics[stat.Name]
+			if !ok {
+				continue
+			}
+
+			//special case for histograms
+			if stat.Name == "conference_sizes" || stat.Name == "conferences_by_audio_senders" || stat.Name == "conferences_by_video_senders" {
+				buckets, count, sum := bucketsHelper(stat.Value)
+				m, err := prometheus.NewConstHistogram(metric.desc, count, float64(sum), buckets, set.jvbIdentifier)
+
+				if err != nil {
+					fmt.Printf("Unable to publish metric %s: %s\n", metric.name, err.Error())
+					continue
+				}
+
+				metrics <- m
+				continue
+			}
+
+			//simple metrics
+			value, err := strconv.ParseFloat(stat.Value, 64)
+			if err != nil {
+				fmt.Printf("unable to convert value %s to numeric: %s\n", stat.Value, err.Error())
+				continue
+			}
+			m, err := prometheus.NewConstMetric(metric.desc, metric.metricType, value, set.jvbIdentifier)
+			if err != nil {
+				fmt.Printf("Unable to create metric %s: %s\n", metric.name, err.Error())
+				continue
+			}
+			metrics <- m
+		}
+	}
+}
+
+//registerUnknownStats scans the currently cached stats for names that aren't registered yet and adds them
+//as gauges, so permissive mode surfaces new JVB stats without requiring a code change. It takes its own
+//read lock to find candidates and lets RegisterStat take the write lock to add them, rather than holding
+//a single lock across both steps.
+func (c *JvbCollector) registerUnknownStats() {
+	c.mutex.RLock()
+	var unknown = make(map[string]struct{})
 	for _, set := range c.statsSets {
-		if time.Since(set.lastUpdated) <= c.Retention {
-
-			//match metric names with stats
-			for _, stat := range set.stats.Stats {
-				for _, metric := range c.metrics {
-					if metric.name == c.NamePrefix+stat.Name {
-
-						//special case for histograms
-						if stat.Name == "conference_sizes" || stat.Name == "conferences_by_audio_senders" || stat.Name == "conferences_by_video_senders" {
-							buckets, sum := bucketsHelper(stat.Value)
-							m, err := prometheus.NewConstHistogram(metric.desc, sum, float64(sum), buckets, set.jvbIdentifier)
-
-							if err != nil {
-								fmt.Printf("Unable to publish metric %s: %s\n", metric.name, err.Error())
-								continue
-							}
-
-							metrics <- m
-							continue
-						}
-
-						//simple metrics
-						value, err := strconv.ParseFloat(stat.Value, 64)
-						if err != nil {
-							fmt.Printf("unable to convert value %s to numeric: %s\n", stat.Value, err.Error())
-							continue
-						}
-						m, err := prometheus.NewConstMetric(metric.desc, metric.metricType, float64(value), set.jvbIdentifier)
-						if err != nil {
-							fmt.Printf("Unable to create metric %s: %s\n", metric.name, err.Error())
-							continue
-						}
-						metrics <- m
-					}
+		if time.Since(set.lastUpdated) > c.Retention {
+			continue
+		}
+		for _, stat := range set.stats.Stats {
+			if _, ok := c.metrics[stat.Name]; !ok {
+				if _, err := strconv.ParseFloat(stat.Value, 64); err == nil {
+					unknown[stat.Name] = struct{}{}
 				}
 			}
 		}
 	}
+	c.mutex.RUnlock()
+
+	for name := range unknown {
+		c.RegisterStat(name, prometheus.GaugeValue,
+			fmt.Sprintf("Automatically registered gauge for the %q stat (permissive mode).", name))
+	}
 }
 
-//Update updates the cached stats for the JVB identified by identifier, inserts a new stats set if none present yet.
+//Update updates the cached stats for the JVB identified by identifier, inserts a new stats set if none present yet,
+//and garbage-collects any stats set that has been stale for more than 2*Retention so that churning, autoscaled
+//JVB identifiers don't leak memory.
 //identifier: any string that identifies the specific JVB, you might want to consider using the node part of the JVB jid (<node>@<domain>/<resource>)
 //	instead of the whole jid. This helps to keep track of JVBs being autoscaled
 //stats: as they are unmarshalled by the PresExtension
 func (c *JvbCollector) Update(identifier string, stats *Stats) {
-	for i, s := range c.statsSets {
-		if s.jvbIdentifier == identifier {
-			c.statsSets[i].lastUpdated = time.Now()
-			c.statsSets[i].stats = *stats
-			return
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for id, s := range c.statsSets {
+		if time.Since(s.lastUpdated) > 2*c.Retention {
+			delete(c.statsSets, id)
 		}
 	}
 
-	c.statsSets = append(c.statsSets, statsSet{
+	c.statsSets[identifier] = statsSet{
 		lastUpdated:   time.Now(),
 		stats:         *stats,
 		jvbIdentifier: identifier,
-	})
+	}
 }
 
-func bucketsHelper(value string) (histogram map[float64]uint64, sum uint64) {
+//bucketsHelper parses the flat, comma separated bucket values reported by a JVB (e.g. "[0,1,2,3]") into
+//cumulative histogram buckets, plus the count and sum expected by prometheus.NewConstHistogram.
+//Convention: the value at index i is the number of conferences with exactly i participants/senders, and
+//the last value is the overflow bucket collapsed into +Inf, so count is the total number of conferences
+//and sum is Σ i*values[i] across all buckets, including the overflow one.
+func bucketsHelper(value string) (histogram map[float64]uint64, count uint64, sum uint64) {
 	histogram = make(map[float64]uint64)
 	value = strings.Trim(value, "[]")
 	var values []uint64
@@ -357,10 +382,15 @@ func bucketsHelper(value string) (histogram map[float64]uint64, sum uint64) {
 		values = append(values, vuint)
 	}
 
-	//calculate sum (makes this metric independent from conferences metric)
-	sum = 0
+	//count is the total number of conferences, independent of how they are distributed across buckets
 	for _, v := range values {
-		sum += v
+		count += v
+	}
+
+	//sum estimates the true observation mass by weighting each bucket by its label (the conference size
+	//it represents), so that rate(_sum)/rate(_count) yields the average conference size
+	for i, v := range values {
+		sum += uint64(i) * v
 	}
 
 	//for the histgram buckets we need to omit the last field b/c the +inf bucket is added automatically
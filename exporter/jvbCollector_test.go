@@ -0,0 +1,124 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestBucketsHelper(t *testing.T) {
+	buckets, count, sum := bucketsHelper("[0,1,2,3]")
+
+	if count != 6 {
+		t.Errorf("expected count 6, got %d", count)
+	}
+
+	if sum != 14 {
+		t.Errorf("expected sum 14, got %d", sum)
+	}
+
+	var expected = map[float64]uint64{0: 0, 1: 1, 2: 3}
+	if !reflect.DeepEqual(buckets, expected) {
+		t.Errorf("expected buckets %v, got %v", expected, buckets)
+	}
+}
+
+//TestJvbCollectorConcurrency hammers Update from N goroutines while a scraper goroutine calls Collect,
+//intended to be run with -race.
+func TestJvbCollectorConcurrency(t *testing.T) {
+	var collector = NewJvbCollector("jitsi", "", time.Minute)
+
+	var stats = &Stats{Stats: []Stat{
+		{Name: "participants", Value: "3"},
+		{Name: "conference_sizes", Value: "[0,1,2,3]"},
+	}}
+
+	var updaters sync.WaitGroup
+	var stop = make(chan struct{})
+	var collectorDone = make(chan struct{})
+
+	go func() {
+		defer close(collectorDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				var ch = make(chan prometheus.Metric, 32)
+				go func() {
+					collector.Collect(ch)
+					close(ch)
+				}()
+				for range ch {
+				}
+			}
+		}
+	}()
+
+	for i := 0; i < 8; i++ {
+		updaters.Add(1)
+		go func(i int) {
+			defer updaters.Done()
+			for j := 0; j < 100; j++ {
+				collector.Update("jvb-race", stats)
+			}
+		}(i)
+	}
+
+	updaters.Wait()
+	close(stop)
+	<-collectorDone
+}
+
+func TestPermissiveModeRegistersUnknownStats(t *testing.T) {
+	var collector = NewJvbCollector("jitsi", "", time.Minute)
+	collector.Permissive = true
+
+	collector.Update("jvb1", &Stats{Stats: []Stat{
+		{Name: "some_new_jvb_stat", Value: "42"},
+	}})
+
+	var ch = make(chan prometheus.Metric, 32)
+	collector.Collect(ch)
+	close(ch)
+
+	if _, ok := collector.metrics["some_new_jvb_stat"]; !ok {
+		t.Fatalf("expected permissive mode to auto-register some_new_jvb_stat")
+	}
+
+	var found bool
+	for m := range ch {
+		var dtoMetric = &dto.Metric{}
+		if err := m.Write(dtoMetric); err != nil {
+			t.Fatalf("unable to write metric: %s", err.Error())
+		}
+		if dtoMetric.GetGauge().GetValue() == 42 {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Errorf("expected Collect to publish the auto-registered gauge with value 42")
+	}
+}
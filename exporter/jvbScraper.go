@@ -0,0 +1,283 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//ScrapeTarget describes a single JVB instance to be pulled via its COLIBRI stats REST endpoint
+//Identifier: attached as the jvb_instance label, same meaning as the identifier passed to JvbCollector.Update
+//URL: full URL of the stats endpoint, e.g. http://jvb1.example.com:8080/colibri/stats
+//CertFile/KeyFile: optional client certificate used for mTLS against the target
+//CAFile: optional CA bundle used to verify the target's server certificate
+//Username/Password: optional basic-auth credentials sent with the scrape request
+type ScrapeTarget struct {
+	Identifier string
+	URL        string
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	Username   string
+	Password   string
+}
+
+//JvbScraper periodically pulls COLIBRI stats from a set of JVBs and feeds them into a JvbCollector,
+//as an alternative to the XMPP presence based PresExtension push path
+type JvbScraper struct {
+	Collector    *JvbCollector
+	Timeout      time.Duration
+	TargetsFile  string
+	targets      []ScrapeTarget
+	targetsMutex sync.RWMutex
+
+	scrapeDuration *prometheus.HistogramVec
+	scrapeUp       *prometheus.GaugeVec
+	scrapeErrors   *prometheus.CounterVec
+}
+
+//NewJvbScraper initializes a JvbScraper feeding the given collector
+//targets: initial, static set of scrape targets; may be empty if targetsFile is used instead
+//timeout: per-scrape HTTP timeout applied to every target
+//registerer: registry the scraper's own meta-metrics are registered on, usually the same registry the JvbCollector is registered on
+func NewJvbScraper(collector *JvbCollector, targets []ScrapeTarget, timeout time.Duration, registerer prometheus.Registerer) *JvbScraper {
+	var scraper = &JvbScraper{
+		Collector: collector,
+		Timeout:   timeout,
+		targets:   targets,
+	}
+
+	scraper.scrapeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "jitsi_scrape_duration_seconds",
+		Help: "Duration of the COLIBRI stats scrape for a single jvb_instance.",
+	}, []string{"jvb_instance"})
+
+	scraper.scrapeUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jitsi_scrape_up",
+		Help: "Whether the last COLIBRI stats scrape of the jvb_instance succeeded (1) or not (0).",
+	}, []string{"jvb_instance"})
+
+	scraper.scrapeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jitsi_scrape_errors_total",
+		Help: "Total number of failed COLIBRI stats scrapes, per jvb_instance.",
+	}, []string{"jvb_instance"})
+
+	registerer.MustRegister(scraper.scrapeDuration, scraper.scrapeUp, scraper.scrapeErrors)
+
+	return scraper
+}
+
+//LoadTargetsFile loads the initial set of scrape targets from a JSON file and arranges for it to be
+//reloaded whenever the process receives SIGHUP. The file is expected to contain a JSON array of ScrapeTarget.
+func (s *JvbScraper) LoadTargetsFile(path string) error {
+	s.TargetsFile = path
+
+	if err := s.reloadTargetsFile(); err != nil {
+		return err
+	}
+
+	var sighup = make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			if err := s.reloadTargetsFile(); err != nil {
+				fmt.Printf("unable to reload scrape targets from %s: %s\n", s.TargetsFile, err.Error())
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *JvbScraper) reloadTargetsFile() error {
+	data, err := ioutil.ReadFile(s.TargetsFile)
+	if err != nil {
+		return err
+	}
+
+	var targets []ScrapeTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return err
+	}
+
+	s.targetsMutex.Lock()
+	s.targets = targets
+	s.targetsMutex.Unlock()
+
+	return nil
+}
+
+//Run starts scraping all configured targets every interval, until stop is closed
+func (s *JvbScraper) Run(interval time.Duration, stop <-chan struct{}) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.scrapeAll()
+	for {
+		select {
+		case <-ticker.C:
+			s.scrapeAll()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *JvbScraper) scrapeAll() {
+	s.targetsMutex.RLock()
+	var targets = make([]ScrapeTarget, len(s.targets))
+	copy(targets, s.targets)
+	s.targetsMutex.RUnlock()
+
+	for _, target := range targets {
+		s.scrapeTarget(target)
+	}
+}
+
+func (s *JvbScraper) scrapeTarget(target ScrapeTarget) {
+	var start = time.Now()
+	stats, err := s.fetch(target)
+	var duration = time.Since(start).Seconds()
+	s.scrapeDuration.WithLabelValues(target.Identifier).Observe(duration)
+
+	if err != nil {
+		fmt.Printf("unable to scrape jvb %s at %s: %s\n", target.Identifier, target.URL, err.Error())
+		s.scrapeUp.WithLabelValues(target.Identifier).Set(0)
+		s.scrapeErrors.WithLabelValues(target.Identifier).Inc()
+		return
+	}
+
+	s.scrapeUp.WithLabelValues(target.Identifier).Set(1)
+	s.Collector.Update(target.Identifier, stats)
+}
+
+func (s *JvbScraper) fetch(target ScrapeTarget) (*Stats, error) {
+	client, err := httpClientFor(target, s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Username != "" || target.Password != "" {
+		req.SetBasicAuth(target.Username, target.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var raw map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	return statsFromColibri(raw), nil
+}
+
+//httpClientFor builds an *http.Client honouring the per-target TLS configuration (client cert and CA bundle)
+//and the scraper's global timeout
+func httpClientFor(target ScrapeTarget, timeout time.Duration) (*http.Client, error) {
+	var tlsConfig = &tls.Config{}
+
+	if target.CertFile != "" && target.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(target.CertFile, target.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate for %s: %s", target.Identifier, err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if target.CAFile != "" {
+		ca, err := ioutil.ReadFile(target.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle for %s: %s", target.Identifier, err.Error())
+		}
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("unable to parse CA bundle for %s", target.Identifier)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+//statsFromColibri converts the flat key/value map returned by /colibri/stats (or /stats) into the same
+//Stats shape used by the XMPP presence path, so both paths can feed JvbCollector.Update identically
+func statsFromColibri(raw map[string]interface{}) *Stats {
+	var stats = &Stats{}
+
+	for name, value := range raw {
+		stats.Stats = append(stats.Stats, Stat{
+			Name:  name,
+			Value: colibriValueToString(value),
+		})
+	}
+
+	return stats
+}
+
+func colibriValueToString(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		var s = "["
+		for i, e := range v {
+			if i > 0 {
+				s += ","
+			}
+			s += colibriValueToString(e)
+		}
+		return s + "]"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestColibriValueToString(t *testing.T) {
+	var cases = []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"number", float64(42), "42"},
+		{"fractional number", float64(1.5), "1.5"},
+		{"bool true", true, "true"},
+		{"bool false", false, "false"},
+		{"string", "hello", "hello"},
+		{"histogram array", []interface{}{float64(0), float64(1), float64(2), float64(3)}, "[0,1,2,3]"},
+		{"nested array", []interface{}{[]interface{}{float64(1), float64(2)}, float64(3)}, "[[1,2],3]"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var actual = colibriValueToString(c.value)
+			if actual != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStatsFromColibri(t *testing.T) {
+	var raw = map[string]interface{}{
+		"participants":     float64(3),
+		"conference_sizes": []interface{}{float64(0), float64(1), float64(2), float64(3)},
+		"octo_enabled":     true,
+	}
+
+	var stats = statsFromColibri(raw)
+
+	var byName = make(map[string]string)
+	for _, stat := range stats.Stats {
+		byName[stat.Name] = stat.Value
+	}
+
+	if len(byName) != len(raw) {
+		t.Fatalf("expected %d stats, got %d", len(raw), len(byName))
+	}
+
+	if byName["participants"] != "3" {
+		t.Errorf("expected participants=3, got %q", byName["participants"])
+	}
+
+	if byName["conference_sizes"] != "[0,1,2,3]" {
+		t.Errorf("expected conference_sizes=[0,1,2,3], got %q", byName["conference_sizes"])
+	}
+
+	if byName["octo_enabled"] != "true" {
+		t.Errorf("expected octo_enabled=true, got %q", byName["octo_enabled"])
+	}
+
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var expectedNames = []string{"conference_sizes", "octo_enabled", "participants"}
+	for i, name := range names {
+		if name != expectedNames[i] {
+			t.Errorf("expected stat names %v, got %v", expectedNames, names)
+			break
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	var (
+		listenAddress     = flag.String("web.listen-address", ":9493", "Address to listen on for the metrics endpoint.")
+		webConfigFile     = flag.String("web.config.file", "", "Path to a YAML file enabling TLS and/or basic auth on the metrics endpoint.")
+		retention         = flag.Duration("retention", 2*time.Minute, "How long a JVB's last reported stats remain valid once it stops updating.")
+		permissive        = flag.Bool("permissive", false, "Auto-register a gauge for any numeric stat reported by a JVB that isn't already known, instead of silently dropping it.")
+		scrapeTargetsFile = flag.String("scrape.targets-file", "", "Path to a JSON file listing JVBs to pull /colibri/stats from, reloaded on SIGHUP. Leave empty to disable scraping; this binary has no other source of JVB stats.")
+		scrapeInterval    = flag.Duration("scrape.interval", 15*time.Second, "How often to pull /colibri/stats from the configured scrape targets.")
+		scrapeTimeout     = flag.Duration("scrape.timeout", 5*time.Second, "Per-target HTTP timeout applied to /colibri/stats scrapes.")
+	)
+	flag.Parse()
+
+	var registry = prometheus.NewRegistry()
+	var collector = NewJvbCollector("jitsi", "", *retention)
+	collector.Permissive = *permissive
+	registry.MustRegister(collector)
+
+	var stopScraper = make(chan struct{})
+	if *scrapeTargetsFile != "" {
+		var scraper = NewJvbScraper(collector, nil, *scrapeTimeout, registry)
+		if err := scraper.LoadTargetsFile(*scrapeTargetsFile); err != nil {
+			fmt.Printf("unable to load scrape targets from %s: %s\n", *scrapeTargetsFile, err.Error())
+			os.Exit(1)
+		}
+		go scraper.Run(*scrapeInterval, stopScraper)
+	}
+
+	server, err := NewServer(*listenAddress, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), *webConfigFile)
+	if err != nil {
+		fmt.Printf("unable to start server: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("server error: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	var stop = make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+	<-stop
+
+	close(stopScraper)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+}
@@ -0,0 +1,177 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+//Server wraps the metrics HTTP listener with the optional TLS and basic-auth configuration
+//read from a web.config.file, following the pattern the Prometheus ecosystem uses for its exporters
+type Server struct {
+	Addr       string
+	Handler    http.Handler
+	httpServer *http.Server
+	webConfig  *webConfigStore
+}
+
+//NewServer builds a Server serving handler on addr, optionally secured according to webConfigFile.
+//webConfigFile may be empty, in which case the server listens in plain HTTP with no auth, as before.
+func NewServer(addr string, handler http.Handler, webConfigFile string) (*Server, error) {
+	store, err := newWebConfigStore(webConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var server = &Server{
+		Addr:      addr,
+		Handler:   handler,
+		webConfig: store,
+	}
+
+	if webConfigFile != "" {
+		var sighup = make(chan os.Signal, 1)
+		signal.Notify(sighup, syscall.SIGHUP)
+		go func() {
+			for range sighup {
+				server.webConfig.reload()
+			}
+		}()
+	}
+
+	return server, nil
+}
+
+//ListenAndServe starts serving, blocking until the server is shut down via Shutdown or fails to start.
+//Whether TLS is enabled at all is decided once, from the web.config.file as it reads at startup, but the
+//certificate itself is re-read from disk on every handshake via tlsConfig.GetCertificate, so a
+//SIGHUP-triggered certificate rotation takes effect on the next accepted connection without a restart.
+func (s *Server) ListenAndServe() error {
+	var cfg = s.webConfig.get()
+
+	s.httpServer = &http.Server{
+		Addr:    s.Addr,
+		Handler: basicAuthMiddleware(s.Handler, s.webConfig),
+	}
+
+	if !cfg.hasTLS() {
+		return s.httpServer.ListenAndServe()
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg.TLSServerConfig, s.webConfig)
+	if err != nil {
+		return err
+	}
+	s.httpServer.TLSConfig = tlsConfig
+
+	// cert/key are served via tlsConfig.GetCertificate, so no filenames are passed here
+	return s.httpServer.ListenAndServeTLS("", "")
+}
+
+//Shutdown gracefully stops the server, waiting for in-flight requests to finish within ctx's deadline
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(ctx)
+}
+
+//clientAuthTypes maps the client_auth_type values documented for tls_server_config (mirroring the
+//Prometheus exporter-toolkit web-config) to the corresponding tls.ClientAuthType
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                           tls.RequireAndVerifyClientCert,
+	"NoClientCert":               tls.NoClientCert,
+	"RequestClientCert":          tls.RequestClientCert,
+	"RequireAnyClientCert":       tls.RequireAnyClientCert,
+	"VerifyClientCertIfGiven":    tls.VerifyClientCertIfGiven,
+	"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+}
+
+//buildTLSConfig wires up the tls.Config for the listener. The certificate is loaded lazily via
+//GetCertificate, re-reading webConfig's current CertFile/KeyFile on every handshake, so that a
+//SIGHUP reload of web.config.file rotates the serving certificate without a process restart.
+func buildTLSConfig(cfg TLSServerConfig, webConfig *webConfigStore) (*tls.Config, error) {
+	var tlsConfig = &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			var current = webConfig.get().TLSServerConfig
+			cert, err := tls.LoadX509KeyPair(current.CertFile, current.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+			return &cert, nil
+		},
+	}
+
+	if cfg.ClientCAs != "" {
+		ca, err := ioutil.ReadFile(cfg.ClientCAs)
+		if err != nil {
+			return nil, err
+		}
+
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, err
+		}
+
+		tlsConfig.ClientCAs = pool
+
+		clientAuth, ok := clientAuthTypes[cfg.ClientAuth]
+		if !ok {
+			return nil, fmt.Errorf("unknown client_auth_type %q", cfg.ClientAuth)
+		}
+		tlsConfig.ClientAuth = clientAuth
+	}
+
+	return tlsConfig, nil
+}
+
+//basicAuthMiddleware enforces basic_auth_users from the current web config, when any are configured.
+//Passwords are stored and compared as bcrypt hashes, never in cleartext.
+func basicAuthMiddleware(next http.Handler, webConfig *webConfigStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var users = webConfig.get().BasicAuthUsers
+		if len(users) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		username, password, ok := r.BasicAuth()
+		hash, known := users[username]
+		if !ok || !known || !validPassword(hash, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="jitsi-prom-exporter"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
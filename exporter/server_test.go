@@ -0,0 +1,277 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("unable to hash password: %s", err.Error())
+	}
+
+	var store = &webConfigStore{cfg: WebConfig{BasicAuthUsers: map[string]string{"admin": string(hash)}}}
+	var handler = basicAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), store)
+
+	var ts = httptest.NewServer(handler)
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", resp.StatusCode)
+	}
+
+	req.SetBasicAuth("admin", "wrong")
+	resp, err = ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with wrong password, got %d", resp.StatusCode)
+	}
+
+	req.SetBasicAuth("admin", "secret")
+	resp, err = ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 with correct credentials, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerTLS(t *testing.T) {
+	var dir = t.TempDir()
+
+	cert := selfSignedCertPEM(t)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(certFile, cert.certPEM, 0644); err != nil {
+		t.Fatalf("unable to write cert: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(keyFile, cert.keyPEM, 0644); err != nil {
+		t.Fatalf("unable to write key: %s", err.Error())
+	}
+
+	webConfigFile := filepath.Join(dir, "web-config.yml")
+	var contents = "tls_server_config:\n  cert_file: " + certFile + "\n  key_file: " + keyFile + "\n"
+	if err := ioutil.WriteFile(webConfigFile, []byte(contents), 0644); err != nil {
+		t.Fatalf("unable to write web config: %s", err.Error())
+	}
+
+	// reserve a free port, then hand it straight to the server so the test can dial a known address
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to reserve a port: %s", err.Error())
+	}
+	var addr = listener.Addr().String()
+	listener.Close()
+
+	server, err := NewServer(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), webConfigFile)
+	if err != nil {
+		t.Fatalf("unable to build server: %s", err.Error())
+	}
+
+	var serveErr = make(chan error, 1)
+	go func() { serveErr <- server.ListenAndServe() }()
+	defer server.Shutdown(context.Background())
+
+	var pool = x509.NewCertPool()
+	pool.AppendCertsFromPEM(cert.certPEM)
+	var client = &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		select {
+		case err := <-serveErr:
+			t.Fatalf("server exited early: %s", err.Error())
+		default:
+		}
+
+		resp, err = client.Get("https://" + addr + "/")
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("unable to reach server over TLS: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+//TestServerTLSCertRotation verifies that repointing tls_server_config at a new cert/key pair and
+//sending SIGHUP rotates the certificate the listener serves without restarting the process.
+func TestServerTLSCertRotation(t *testing.T) {
+	var dir = t.TempDir()
+
+	certA := selfSignedCertPEM(t)
+	certFileA := filepath.Join(dir, "cert-a.pem")
+	keyFileA := filepath.Join(dir, "key-a.pem")
+	if err := ioutil.WriteFile(certFileA, certA.certPEM, 0644); err != nil {
+		t.Fatalf("unable to write cert: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(keyFileA, certA.keyPEM, 0644); err != nil {
+		t.Fatalf("unable to write key: %s", err.Error())
+	}
+
+	webConfigFile := filepath.Join(dir, "web-config.yml")
+	var contents = "tls_server_config:\n  cert_file: " + certFileA + "\n  key_file: " + keyFileA + "\n"
+	if err := ioutil.WriteFile(webConfigFile, []byte(contents), 0644); err != nil {
+		t.Fatalf("unable to write web config: %s", err.Error())
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to reserve a port: %s", err.Error())
+	}
+	var addr = listener.Addr().String()
+	listener.Close()
+
+	server, err := NewServer(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), webConfigFile)
+	if err != nil {
+		t.Fatalf("unable to build server: %s", err.Error())
+	}
+
+	var serveErr = make(chan error, 1)
+	go func() { serveErr <- server.ListenAndServe() }()
+	defer server.Shutdown(context.Background())
+
+	poolA := x509.NewCertPool()
+	poolA.AppendCertsFromPEM(certA.certPEM)
+	clientA := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: poolA}}}
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		resp, err = clientA.Get("https://" + addr + "/")
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("unable to reach server over TLS before rotation: %s", err.Error())
+	}
+	resp.Body.Close()
+
+	// point tls_server_config at a brand new cert/key pair, then SIGHUP to reload it
+	certB := selfSignedCertPEM(t)
+	certFileB := filepath.Join(dir, "cert-b.pem")
+	keyFileB := filepath.Join(dir, "key-b.pem")
+	if err := ioutil.WriteFile(certFileB, certB.certPEM, 0644); err != nil {
+		t.Fatalf("unable to write rotated cert: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(keyFileB, certB.keyPEM, 0644); err != nil {
+		t.Fatalf("unable to write rotated key: %s", err.Error())
+	}
+	contents = "tls_server_config:\n  cert_file: " + certFileB + "\n  key_file: " + keyFileB + "\n"
+	if err := ioutil.WriteFile(webConfigFile, []byte(contents), 0644); err != nil {
+		t.Fatalf("unable to rewrite web config: %s", err.Error())
+	}
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("unable to signal SIGHUP: %s", err.Error())
+	}
+
+	poolB := x509.NewCertPool()
+	poolB.AppendCertsFromPEM(certB.certPEM)
+	clientB := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: poolB}}}
+
+	for i := 0; i < 50; i++ {
+		resp, err = clientB.Get("https://" + addr + "/")
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server still serving the pre-rotation certificate after SIGHUP: %s", err.Error())
+	}
+	resp.Body.Close()
+}
+
+type selfSignedCert struct {
+	certPEM []byte
+	keyPEM  []byte
+}
+
+//selfSignedCertPEM generates a throwaway self-signed certificate/key pair for TestServerTLS,
+//valid for localhost and 127.0.0.1 only
+func selfSignedCertPEM(t *testing.T) selfSignedCert {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate key: %s", err.Error())
+	}
+
+	var template = x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %s", err.Error())
+	}
+
+	var certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	var keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return selfSignedCert{certPEM: certPEM, keyPEM: keyPEM}
+}
@@ -0,0 +1,34 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+//Stats is the flat set of name/value pairs reported by a JVB, as unmarshalled from the COLIBRI stats
+//XMPP presence extension pushed by a PresExtension consumer, or from the equivalent /colibri/stats
+//REST endpoint via statsFromColibri.
+type Stats struct {
+	Stats []Stat `xml:"stat"`
+}
+
+//Stat is a single flat statistic as reported by a JVB.
+//Name: the un-prefixed stat name (e.g. "participants" or "conference_sizes")
+//Value: its string representation, which may be a bare number, a bracketed list for histogram stats
+//	(see bucketsHelper), or a boolean
+type Stat struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
@@ -0,0 +1,92 @@
+/*
+ *  Copyright 2019 karriere tutor GmbH
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+//TLSServerConfig configures the server side of the metrics listener, mirroring the
+//tls_server_config block used across the Prometheus ecosystem (node_exporter, alertmanager, ...)
+type TLSServerConfig struct {
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ClientCAs  string `yaml:"client_ca_file"`
+	ClientAuth string `yaml:"client_auth_type"`
+}
+
+//WebConfig is the schema of the file passed via the web.config.file flag, loaded on startup and
+//reloaded whenever the process receives SIGHUP
+type WebConfig struct {
+	TLSServerConfig TLSServerConfig  `yaml:"tls_server_config"`
+	BasicAuthUsers  map[string]string `yaml:"basic_auth_users"`
+}
+
+//hasTLS reports whether the config carries enough material to serve TLS
+func (c WebConfig) hasTLS() bool {
+	return c.TLSServerConfig.CertFile != "" && c.TLSServerConfig.KeyFile != ""
+}
+
+//webConfigStore holds the currently active WebConfig behind a mutex so a SIGHUP reload
+//cannot race with an in-flight request being authenticated
+type webConfigStore struct {
+	mutex sync.RWMutex
+	path  string
+	cfg   WebConfig
+}
+
+func newWebConfigStore(path string) (*webConfigStore, error) {
+	var store = &webConfigStore{path: path}
+
+	if path == "" {
+		return store, nil
+	}
+
+	if err := store.reload(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *webConfigStore) reload() error {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	var cfg WebConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.cfg = cfg
+	s.mutex.Unlock()
+
+	return nil
+}
+
+func (s *webConfigStore) get() WebConfig {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.cfg
+}